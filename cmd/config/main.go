@@ -4,228 +4,299 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"regexp"
-	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/patrickbucher/meow"
+	"github.com/patrickbucher/meow/events"
+	"github.com/patrickbucher/meow/httpcache"
+	"github.com/patrickbucher/meow/middleware"
+	"github.com/patrickbucher/meow/monitor"
+	"github.com/patrickbucher/meow/store"
 	"github.com/valkey-io/valkey-go"
 )
 
 func main() {
-	valkeyURL, ok := os.LookupEnv("VALKEY_URL")
-	if !ok {
-		fmt.Fprintln(os.Stderr, "environment variable VALKEY_URL must be set")
+	storeKind := flag.String("store", "valkey", `storage backend: "valkey", "memory", or "postgres"`)
+	storeDSN := flag.String("store-dsn", os.Getenv("STORE_DSN"), "connection string for the postgres backend")
+	authToken := flag.String("auth-token", os.Getenv("AUTH_TOKEN"), "bearer token required of clients; disabled if empty")
+	cacheTTL := flag.Duration("cache-ttl", 10*time.Second, "TTL for cached GET /endpoints responses")
+	logLevel := flag.String("log-level", "info", "initial log level: debug, info, warn, or error")
+	addr := flag.String("addr", "localhost", "listen to address")
+	port := flag.Uint("port", 8000, "listen on port")
+	flag.Parse()
+
+	log.SetOutput(os.Stderr)
+
+	levelVar := new(slog.LevelVar)
+	if err := levelVar.UnmarshalText([]byte(*logLevel)); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -log-level %q: %v\n", *logLevel, err)
 		os.Exit(1)
 	}
-	println(valkeyURL)
-	options := valkey.ClientOption{
-		InitAddress: []string{"valkey.frickelcloud.ch:6379"},
-		SelectDB:    27,
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: levelVar})))
+
+	var client valkey.Client
+	if *storeKind == "" || *storeKind == "valkey" {
+		_, ok := os.LookupEnv("VALKEY_URL")
+		if !ok {
+			fmt.Fprintln(os.Stderr, "environment variable VALKEY_URL must be set")
+			os.Exit(1)
+		}
+		options := valkey.ClientOption{
+			InitAddress: []string{"valkey.frickelcloud.ch:6379"},
+			SelectDB:    27,
+		}
+		var err error
+		client, err = valkey.NewClient(options)
+		if err != nil {
+			log.Fatalf("connect to Valkey: %v", err)
+		}
+		defer client.Close()
 	}
-	client, err := valkey.NewClient(options)
+
+	endpointStore, err := store.New(*storeKind, *storeDSN, client)
 	if err != nil {
-		log.Fatalf("connect to Valkey: %v", err)
+		log.Fatalf("set up %q store: %v", *storeKind, err)
 	}
-	defer client.Close()
 
-	addr := flag.String("addr", "localhost", "listen to address")
-	port := flag.Uint("port", 8000, "listen on port")
-	flag.Parse()
+	publisher := events.New(*storeKind, client)
 
-	log.SetOutput(os.Stderr)
+	scheduler := monitor.NewScheduler(endpointStore, publisher)
+	if err := scheduler.Start(context.Background()); err != nil {
+		log.Fatalf("start monitor scheduler: %v", err)
+	}
 
-	http.HandleFunc("/endpoints/", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			getEndpoint(w, r, client)
-		case http.MethodPost:
-			postEndpoint(w, r, client)
-		default:
-			log.Printf("request from %s rejected: method %s not allowed",
-				r.RemoteAddr, r.Method)
-			w.WriteHeader(http.StatusMethodNotAllowed)
-		}
-	})
-	http.HandleFunc("/endpoints", func(w http.ResponseWriter, r *http.Request) {
-		getEndpoints(w, r, client)
-	})
+	cache := httpcache.NewCache(*cacheTTL)
+	handler := newRouter(endpointStore, scheduler, *authToken, cache, publisher, levelVar)
 
 	listenTo := fmt.Sprintf("%s:%d", *addr, *port)
 	log.Printf("listen to %s", listenTo)
-	http.ListenAndServe(listenTo, nil)
+	http.ListenAndServe(listenTo, handler)
 }
 
-func getEndpoint(w http.ResponseWriter, r *http.Request, vk valkey.Client) {
-	log.Printf("GET %s from %s", r.URL, r.RemoteAddr)
-	identifier, err := extractEndpointIdentifier(r.URL.String())
-	if err != nil {
-		log.Printf("extract endpoint identifier of %s: %v", r.URL, err)
+// newRouter wires up the API's routes and the middleware chain applied to
+// every request. It is extracted from main so tests can exercise the full
+// stack, including routing and path parameter extraction.
+func newRouter(s store.EndpointStore, scheduler *monitor.Scheduler, authToken string, cache *httpcache.Cache, publisher events.Publisher, logLevel *slog.LevelVar) http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID, middleware.AccessLog, middleware.Recover,
+		middleware.AuthN(authToken), middleware.CORS, middleware.GZip)
+
+	r.Get("/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		getEndpoints(w, r, s, cache)
+	})
+	r.Get("/endpoints/events", func(w http.ResponseWriter, r *http.Request) {
+		events.ServeSSE(w, r, publisher)
+	})
+	r.Post("/debug/loglevel", func(w http.ResponseWriter, r *http.Request) {
+		setLogLevel(w, r, logLevel)
+	})
+	r.Route("/endpoints/{id:[a-z][-a-z0-9]+}", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			getEndpoint(w, r, s, cache)
+		})
+		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+			postEndpoint(w, r, s, scheduler, cache, publisher)
+		})
+		r.Put("/", func(w http.ResponseWriter, r *http.Request) {
+			putEndpoint(w, r, s, scheduler, cache, publisher)
+		})
+		r.Delete("/", func(w http.ResponseWriter, r *http.Request) {
+			deleteEndpoint(w, r, s, scheduler, cache, publisher)
+		})
+		r.Get("/history", func(w http.ResponseWriter, r *http.Request) {
+			monitor.GetHistory(w, r, chi.URLParam(r, "id"), scheduler)
+		})
+	})
+	r.Get("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		monitor.GetMetrics(w, r, scheduler)
+		cache.WriteMetrics(w)
+	})
+
+	return r
+}
+
+// setLogLevel handles POST /debug/loglevel, accepting a JSON body of the
+// form {"level": "debug"} ("debug", "info", "warn", or "error") to change
+// the running log level without restarting the process.
+func setLogLevel(w http.ResponseWriter, r *http.Request, logLevel *slog.LevelVar) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	var body struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		logger.Warn("decode loglevel body failed", "remote_addr", r.RemoteAddr, "err", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	ctx := context.Background()
-	key := fmt.Sprintf("endpoint:%s", identifier)
-
-	kvs, err := vk.Do(ctx, vk.B().Hgetall().Key(key).Build()).AsStrMap()
-	if err != nil || len(kvs) == 0 {
-		log.Printf(`no such endpoint "%s"`, identifier)
-		w.WriteHeader(http.StatusNotFound)
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(body.Level)); err != nil {
+		logger.Warn("unknown log level requested", "remote_addr", r.RemoteAddr, "level", body.Level)
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	payload := meow.EndpointPayload{
-		Identifier:   kvs["identifier"],
-		URL:          kvs["url"],
-		Method:       kvs["method"],
-		StatusOnline: parseUint16(kvs["status_online"]),
-		Frequency:    kvs["frequency"],
-		FailAfter:    parseUint8(kvs["fail_after"]),
-	}
+	logLevel.Set(level)
+	logger.Info("log level changed", "remote_addr", r.RemoteAddr, "level", level.String())
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("serialize payload: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(data)
+func getEndpoint(w http.ResponseWriter, r *http.Request, s store.EndpointStore, cache *httpcache.Cache) {
+	identifier := chi.URLParam(r, "id")
+	logger := middleware.LoggerFromContext(r.Context())
+
+	cache.Handle(w, r, r.URL.String(), func(ctx context.Context) (httpcache.Result, error) {
+		payload, err := s.Get(ctx, identifier)
+		if errors.Is(err, store.ErrNotFound) {
+			logger.Info("endpoint not found", "identifier", identifier, "remote_addr", r.RemoteAddr)
+			return httpcache.Result{Status: http.StatusNotFound}, nil
+		} else if err != nil {
+			logger.Error("get endpoint failed", "identifier", identifier, "remote_addr", r.RemoteAddr, "err", err)
+			return httpcache.Result{Status: http.StatusInternalServerError}, nil
+		}
+
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return httpcache.Result{}, fmt.Errorf("serialize payload: %w", err)
+		}
+		return httpcache.Result{Status: http.StatusOK, Data: data}, nil
+	})
 }
 
-func postEndpoint(w http.ResponseWriter, r *http.Request, vk valkey.Client) {
-	log.Printf("POST %s from %s", r.URL, r.RemoteAddr)
-	buf := bytes.NewBufferString("")
-	io.Copy(buf, r.Body)
-	defer r.Body.Close()
+func postEndpoint(w http.ResponseWriter, r *http.Request, s store.EndpointStore, scheduler *monitor.Scheduler, cache *httpcache.Cache, publisher events.Publisher) {
+	saveEndpoint(w, r, s, scheduler, cache, publisher)
+}
 
-	endpoint, err := meow.EndpointFromJSON(buf.String())
-	if err != nil {
-		log.Printf("parse JSON body: %v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
+func putEndpoint(w http.ResponseWriter, r *http.Request, s store.EndpointStore, scheduler *monitor.Scheduler, cache *httpcache.Cache, publisher events.Publisher) {
+	saveEndpoint(w, r, s, scheduler, cache, publisher)
+}
+
+// saveEndpoint parses the request body as an endpoint definition, stores
+// it, (re-)schedules its health check, invalidates any cached GET
+// responses for it, and publishes a created/updated event. It backs both
+// postEndpoint and putEndpoint, which differ only in HTTP verb.
+func saveEndpoint(w http.ResponseWriter, r *http.Request, s store.EndpointStore, scheduler *monitor.Scheduler, cache *httpcache.Cache, publisher events.Publisher) {
+	identifier := chi.URLParam(r, "id")
+	logger := middleware.LoggerFromContext(r.Context())
 
-	identifierPathParam, err := extractEndpointIdentifier(r.URL.String())
-	if err == nil && identifierPathParam != endpoint.Identifier {
-		log.Printf("identifier mismatch: (resource: %s, body: %s)",
-			identifierPathParam, endpoint.Identifier)
+	payload, err := parseEndpointPayload(r, identifier)
+	if err != nil {
+		logger.Warn("parse endpoint payload failed", "identifier", identifier, "remote_addr", r.RemoteAddr, "err", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	ctx := context.Background()
-	key := fmt.Sprintf("endpoint:%s", endpoint.Identifier)
-
-	exists, err := vk.Do(ctx, vk.B().Exists().Key(key).Build()).AsInt64()
+	created, err := s.Put(r.Context(), payload)
 	if err != nil {
-		log.Printf("check existence: %v", err)
+		logger.Error("store endpoint failed", "identifier", payload.Identifier, "remote_addr", r.RemoteAddr, "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	cmd := vk.B().Hset().Key(key).
-		FieldValue().
-		FieldValue("identifier", endpoint.Identifier).
-		FieldValue("url", endpoint.URL.String()).
-		FieldValue("method", endpoint.Method).
-		FieldValue("status_online", strconv.Itoa(int(endpoint.StatusOnline))).
-		FieldValue("frequency", endpoint.Frequency.String()).
-		FieldValue("fail_after", strconv.Itoa(int(endpoint.FailAfter))).
-		Build()
+	scheduler.Reschedule(context.Background(), payload)
+	cache.Invalidate("/endpoints/"+identifier, "/endpoints")
 
-	if err := vk.Do(ctx, cmd).Error(); err != nil {
-		log.Printf("store endpoint: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	evType := events.Updated
+	if created {
+		evType = events.Created
+	}
+	if err := publisher.Publish(context.Background(), events.Event{
+		Type:       evType,
+		Identifier: payload.Identifier,
+		Timestamp:  time.Now(),
+	}); err != nil {
+		logger.Error("publish event failed", "identifier", payload.Identifier, "event_type", string(evType), "err", err)
 	}
 
-	if exists > 0 {
-		w.WriteHeader(http.StatusNoContent)
-	} else {
+	if created {
 		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
-func getEndpoints(w http.ResponseWriter, r *http.Request, vk valkey.Client) {
-	if r.Method != http.MethodGet {
-		log.Printf("request from %s rejected: method %s not allowed",
-			r.RemoteAddr, r.Method)
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-	log.Printf("GET %s from %s", r.URL, r.RemoteAddr)
-
-	ctx := context.Background()
+func parseEndpointPayload(r *http.Request, identifier string) (meow.EndpointPayload, error) {
+	buf := bytes.NewBufferString("")
+	io.Copy(buf, r.Body)
+	defer r.Body.Close()
 
-	keys, err := vk.Do(ctx, vk.B().Keys().Pattern("endpoint:*").Build()).AsStrSlice()
+	endpoint, err := meow.EndpointFromJSON(buf.String())
 	if err != nil {
-		log.Printf("get keys for endpoint:*: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return meow.EndpointPayload{}, fmt.Errorf("parse JSON body: %w", err)
 	}
 
-	payloads := make([]meow.EndpointPayload, 0)
+	if identifier != "" && endpoint.Identifier != identifier {
+		return meow.EndpointPayload{}, fmt.Errorf("identifier mismatch: (resource: %s, body: %s)",
+			identifier, endpoint.Identifier)
+	}
 
-	for _, key := range keys {
-		kvs, err := vk.Do(ctx, vk.B().Hgetall().Key(key).Build()).AsStrMap()
-		if err != nil {
-			log.Printf("hgetall %s: %v", key, err)
-			continue
-		}
+	return meow.EndpointPayload{
+		Identifier:   endpoint.Identifier,
+		URL:          endpoint.URL.String(),
+		Method:       endpoint.Method,
+		StatusOnline: endpoint.StatusOnline,
+		Frequency:    endpoint.Frequency.String(),
+		FailAfter:    endpoint.FailAfter,
+	}, nil
+}
 
-		payload := meow.EndpointPayload{
-			Identifier:   kvs["identifier"],
-			URL:          kvs["url"],
-			Method:       kvs["method"],
-			StatusOnline: parseUint16(kvs["status_online"]),
-			Frequency:    kvs["frequency"],
-			FailAfter:    parseUint8(kvs["fail_after"]),
-		}
-		payloads = append(payloads, payload)
-	}
+func deleteEndpoint(w http.ResponseWriter, r *http.Request, s store.EndpointStore, scheduler *monitor.Scheduler, cache *httpcache.Cache, publisher events.Publisher) {
+	identifier := chi.URLParam(r, "id")
+	logger := middleware.LoggerFromContext(r.Context())
 
-	data, err := json.Marshal(payloads)
-	if err != nil {
-		log.Printf("serialize payloads: %v", err)
+	if _, err := s.Get(r.Context(), identifier); errors.Is(err, store.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		logger.Error("get endpoint failed", "identifier", identifier, "remote_addr", r.RemoteAddr, "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(data)
-}
 
-const endpointIdentifierPatternRaw = "^/endpoints/([a-z][-a-z0-9]+)$"
+	if err := s.Delete(r.Context(), identifier); err != nil {
+		logger.Error("delete endpoint failed", "identifier", identifier, "remote_addr", r.RemoteAddr, "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-var endpointIdentifierPattern = regexp.MustCompile(endpointIdentifierPatternRaw)
+	scheduler.Remove(identifier)
+	cache.Invalidate("/endpoints/"+identifier, "/endpoints")
 
-func extractEndpointIdentifier(endpoint string) (string, error) {
-	matches := endpointIdentifierPattern.FindStringSubmatch(endpoint)
-	if len(matches) == 0 {
-		return "", fmt.Errorf(`endpoint "%s" does not match pattern "%s"`,
-			endpoint, endpointIdentifierPatternRaw)
+	if err := publisher.Publish(context.Background(), events.Event{
+		Type:       events.Deleted,
+		Identifier: identifier,
+		Timestamp:  time.Now(),
+	}); err != nil {
+		logger.Error("publish event failed", "identifier", identifier, "event_type", string(events.Deleted), "err", err)
 	}
-	return matches[1], nil
-}
 
-func parseUint16(s string) uint16 {
-	val, err := strconv.ParseUint(s, 10, 16)
-	if err != nil {
-		return 0
-	}
-	return uint16(val)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func parseUint8(s string) uint8 {
-	val, err := strconv.ParseUint(s, 10, 8)
-	if err != nil {
-		return 0
-	}
-	return uint8(val)
+func getEndpoints(w http.ResponseWriter, r *http.Request, s store.EndpointStore, cache *httpcache.Cache) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	cache.Handle(w, r, r.URL.String(), func(ctx context.Context) (httpcache.Result, error) {
+		payloads, err := s.List(ctx)
+		if err != nil {
+			logger.Error("list endpoints failed", "remote_addr", r.RemoteAddr, "err", err)
+			return httpcache.Result{Status: http.StatusInternalServerError}, nil
+		}
+
+		data, err := json.Marshal(payloads)
+		if err != nil {
+			return httpcache.Result{}, fmt.Errorf("serialize payloads: %w", err)
+		}
+		return httpcache.Result{Status: http.StatusOK, Data: data}, nil
+	})
 }
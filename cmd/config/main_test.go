@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickbucher/meow"
+	"github.com/patrickbucher/meow/events"
+	"github.com/patrickbucher/meow/httpcache"
+	"github.com/patrickbucher/meow/monitor"
+	"github.com/patrickbucher/meow/store"
+)
+
+// newTestRouter wires a router against s with defaults suitable for unit
+// tests: no auth, a short-lived cache, an in-memory event publisher, and
+// an independent scheduler.
+func newTestRouter(s store.EndpointStore) http.Handler {
+	scheduler := monitor.NewScheduler(s, events.NewMemoryPublisher())
+	return newRouter(s, scheduler, "", httpcache.NewCache(time.Minute), events.NewMemoryPublisher(), new(slog.LevelVar))
+}
+
+func TestPostThenGetEndpoint(t *testing.T) {
+	s := store.NewMemoryStore()
+	handler := newTestRouter(s)
+
+	body := `{
+		"identifier": "example",
+		"url": "https://example.com/health",
+		"method": "GET",
+		"status_online": 200,
+		"frequency": "10s",
+		"fail_after": 3
+	}`
+
+	postReq := httptest.NewRequest(http.MethodPost, "/endpoints/example", strings.NewReader(body))
+	postRec := httptest.NewRecorder()
+	handler.ServeHTTP(postRec, postReq)
+
+	if postRec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, postRec.Code, postRec.Body)
+	}
+
+	getReq := httptest.NewRequest(http.MethodGet, "/endpoints/example", nil)
+	getRec := httptest.NewRecorder()
+	handler.ServeHTTP(getRec, getReq)
+
+	if getRec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, getRec.Code, getRec.Body)
+	}
+
+	var payload meow.EndpointPayload
+	if err := json.Unmarshal(getRec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if payload.Identifier != "example" {
+		t.Errorf("expected identifier %q, got %q", "example", payload.Identifier)
+	}
+}
+
+func TestGetEndpointNotFound(t *testing.T) {
+	s := store.NewMemoryStore()
+	handler := newTestRouter(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/endpoints/missing", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestGetEndpoints(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.Put(context.Background(), meow.EndpointPayload{
+		Identifier:   "example",
+		URL:          "https://example.com/health",
+		Method:       "GET",
+		StatusOnline: 200,
+		Frequency:    "10s",
+		FailAfter:    3,
+	})
+	handler := newTestRouter(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/endpoints", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var payloads []meow.EndpointPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payloads); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(payloads))
+	}
+}
+
+func TestPutUpdatesExistingEndpoint(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.Put(context.Background(), meow.EndpointPayload{
+		Identifier:   "example",
+		URL:          "https://example.com/health",
+		Method:       "GET",
+		StatusOnline: 200,
+		Frequency:    "10s",
+		FailAfter:    3,
+	})
+	handler := newTestRouter(s)
+
+	body := `{
+		"identifier": "example",
+		"url": "https://example.com/health",
+		"method": "GET",
+		"status_online": 204,
+		"frequency": "30s",
+		"fail_after": 5
+	}`
+	req := httptest.NewRequest(http.MethodPut, "/endpoints/example", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body)
+	}
+
+	updated, err := s.Get(context.Background(), "example")
+	if err != nil {
+		t.Fatalf("get updated endpoint: %v", err)
+	}
+	if updated.FailAfter != 5 {
+		t.Errorf("expected fail_after 5, got %d", updated.FailAfter)
+	}
+}
+
+func TestDeleteEndpoint(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.Put(context.Background(), meow.EndpointPayload{
+		Identifier:   "example",
+		URL:          "https://example.com/health",
+		Method:       "GET",
+		StatusOnline: 200,
+		Frequency:    "10s",
+		FailAfter:    3,
+	})
+	handler := newTestRouter(s)
+
+	req := httptest.NewRequest(http.MethodDelete, "/endpoints/example", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body)
+	}
+
+	if _, err := s.Get(context.Background(), "example"); err != store.ErrNotFound {
+		t.Fatalf("expected endpoint to be gone, got err=%v", err)
+	}
+}
+
+func TestDeleteEndpointNotFound(t *testing.T) {
+	s := store.NewMemoryStore()
+	handler := newTestRouter(s)
+
+	req := httptest.NewRequest(http.MethodDelete, "/endpoints/missing", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	s := store.NewMemoryStore()
+	scheduler := monitor.NewScheduler(s, events.NewMemoryPublisher())
+	logLevel := new(slog.LevelVar)
+	handler := newRouter(s, scheduler, "", httpcache.NewCache(time.Minute), events.NewMemoryPublisher(), logLevel)
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/loglevel", strings.NewReader(`{"level":"debug"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body)
+	}
+	if logLevel.Level() != slog.LevelDebug {
+		t.Errorf("expected log level %v, got %v", slog.LevelDebug, logLevel.Level())
+	}
+}
+
+func TestSetLogLevelRejectsUnknownLevel(t *testing.T) {
+	s := store.NewMemoryStore()
+	handler := newTestRouter(s)
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/loglevel", strings.NewReader(`{"level":"verbose"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+// TestServeSSEThroughMiddlewareChain exercises GET /endpoints/events
+// through the production newRouter over a real network connection, not
+// just the bare handler, so that AccessLog's and GZip's ResponseWriter
+// wrapping is actually in the loop. Both must pass Flush() through to
+// the underlying connection for the stream to open at all.
+func TestServeSSEThroughMiddlewareChain(t *testing.T) {
+	s := store.NewMemoryStore()
+	srv := httptest.NewServer(newTestRouter(s))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/endpoints/events", nil)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", ct)
+	}
+}
@@ -0,0 +1,37 @@
+// Package events publishes and streams endpoint lifecycle and status
+// change notifications, backing the GET /endpoints/events SSE endpoint.
+package events
+
+import "time"
+
+// EventType identifies what happened to an endpoint.
+type EventType string
+
+const (
+	Created       EventType = "created"
+	Updated       EventType = "updated"
+	Deleted       EventType = "deleted"
+	StatusChanged EventType = "status_changed"
+)
+
+// Event is a single notification about an endpoint, as published to
+// subscribers of the SSE stream.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       EventType `json:"type"`
+	Identifier string    `json:"identifier"`
+	Timestamp  time.Time `json:"timestamp"`
+	Up         *bool     `json:"up,omitempty"`
+}
+
+func matches(identifiers []string, identifier string) bool {
+	if len(identifiers) == 0 {
+		return true
+	}
+	for _, id := range identifiers {
+		if id == identifier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryPublisherSubscribeReceivesPublishedEvent(t *testing.T) {
+	p := NewMemoryPublisher()
+	ctx := context.Background()
+
+	stream, cancel, err := p.Subscribe(ctx, nil)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer cancel()
+
+	if err := p.Publish(ctx, Event{Type: Created, Identifier: "example"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case ev := <-stream:
+		if ev.Identifier != "example" || ev.Type != Created {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+func TestMemoryPublisherSubscribeFiltersByIdentifier(t *testing.T) {
+	p := NewMemoryPublisher()
+	ctx := context.Background()
+
+	stream, cancel, err := p.Subscribe(ctx, []string{"wanted"})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer cancel()
+
+	p.Publish(ctx, Event{Type: Created, Identifier: "unwanted"})
+	p.Publish(ctx, Event{Type: Created, Identifier: "wanted"})
+
+	select {
+	case ev := <-stream:
+		if ev.Identifier != "wanted" {
+			t.Fatalf("expected only filtered identifier, got %q", ev.Identifier)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+func TestMemoryPublisherReplayResumesAfterLastID(t *testing.T) {
+	p := NewMemoryPublisher()
+	ctx := context.Background()
+
+	p.Publish(ctx, Event{Type: Created, Identifier: "example"})
+	p.Publish(ctx, Event{Type: Updated, Identifier: "example"})
+	p.Publish(ctx, Event{Type: Deleted, Identifier: "example"})
+
+	all, err := p.Replay(ctx, "", nil)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(all))
+	}
+
+	resumed, err := p.Replay(ctx, all[0].ID, nil)
+	if err != nil {
+		t.Fatalf("replay from %q: %v", all[0].ID, err)
+	}
+	if len(resumed) != 2 || resumed[0].Type != Updated {
+		t.Fatalf("expected [updated deleted] after %q, got %+v", all[0].ID, resumed)
+	}
+}
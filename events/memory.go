@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// replayBufferSize caps how many past events a memoryPublisher keeps
+// around for Replay.
+const replayBufferSize = 1000
+
+// memoryPublisher is a Publisher that fans out events to in-process
+// subscribers only. It backs the "memory" and "postgres" store backends,
+// which have no message broker of their own, and is handy in tests.
+type memoryPublisher struct {
+	mu          sync.Mutex
+	seq         uint64
+	buffer      []Event
+	subscribers map[chan Event]struct{}
+}
+
+// NewMemoryPublisher returns a Publisher that keeps everything in memory.
+func NewMemoryPublisher() Publisher {
+	return &memoryPublisher{subscribers: make(map[chan Event]struct{})}
+}
+
+func (p *memoryPublisher) Publish(ctx context.Context, ev Event) error {
+	p.mu.Lock()
+	p.seq++
+	ev.ID = strconv.FormatUint(p.seq, 10)
+	p.buffer = append(p.buffer, ev)
+	if len(p.buffer) > replayBufferSize {
+		p.buffer = p.buffer[len(p.buffer)-replayBufferSize:]
+	}
+	subs := make([]chan Event, 0, len(p.subscribers))
+	for ch := range p.subscribers {
+		subs = append(subs, ch)
+	}
+	p.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	return nil
+}
+
+func (p *memoryPublisher) Subscribe(ctx context.Context, identifiers []string) (<-chan Event, func(), error) {
+	raw := make(chan Event, 16)
+	p.mu.Lock()
+	p.subscribers[raw] = struct{}{}
+	p.mu.Unlock()
+
+	out := make(chan Event, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case ev, ok := <-raw:
+				if !ok {
+					return
+				}
+				if !matches(identifiers, ev.Identifier) {
+					continue
+				}
+				select {
+				case out <- ev:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	cancel := func() {
+		p.mu.Lock()
+		delete(p.subscribers, raw)
+		p.mu.Unlock()
+		close(done)
+	}
+	return out, cancel, nil
+}
+
+func (p *memoryPublisher) Replay(ctx context.Context, lastID string, identifiers []string) ([]Event, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var result []Event
+	replaying := lastID == ""
+	for _, ev := range p.buffer {
+		if !replaying {
+			if ev.ID == lastID {
+				replaying = true
+			}
+			continue
+		}
+		if matches(identifiers, ev.Identifier) {
+			result = append(result, ev)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,14 @@
+package events
+
+import "github.com/valkey-io/valkey-go"
+
+// New builds a Publisher appropriate for the given store kind: a Valkey
+// pub/sub and stream backed Publisher for "valkey", and an in-memory one
+// otherwise ("memory" and "postgres" have no message broker of their
+// own).
+func New(kind string, client valkey.Client) Publisher {
+	if kind == "" || kind == "valkey" {
+		return NewValkeyPublisher(client)
+	}
+	return NewMemoryPublisher()
+}
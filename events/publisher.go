@@ -0,0 +1,21 @@
+package events
+
+import "context"
+
+// Publisher fans out endpoint events to subscribers and keeps a bounded
+// replay buffer so a client that reconnects with a Last-Event-ID can
+// resume instead of missing events published while it was disconnected.
+type Publisher interface {
+	// Publish announces ev to all current subscribers and keeps it for
+	// Replay.
+	Publish(ctx context.Context, ev Event) error
+
+	// Subscribe returns a channel of events matching identifiers (every
+	// endpoint if identifiers is empty), and a function to stop the
+	// subscription and release its resources.
+	Subscribe(ctx context.Context, identifiers []string) (stream <-chan Event, cancel func(), err error)
+
+	// Replay returns the events published after lastID, oldest first,
+	// filtered to identifiers (every endpoint if identifiers is empty).
+	Replay(ctx context.Context, lastID string, identifiers []string) ([]Event, error)
+}
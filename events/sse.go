@@ -0,0 +1,98 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/patrickbucher/meow/middleware"
+)
+
+// heartbeatInterval is how often ServeSSE sends a comment line to keep
+// idle connections (and the proxies in front of them) alive.
+const heartbeatInterval = 15 * time.Second
+
+// ServeSSE upgrades the request to a Server-Sent Events stream of Event
+// values published through publisher. A "filter" query parameter
+// restricts the stream to a comma-separated list of identifiers. A
+// "Last-Event-ID" request header resumes a briefly disconnected client by
+// replaying events missed in the meantime before switching to live
+// delivery.
+func ServeSSE(w http.ResponseWriter, r *http.Request, publisher Publisher) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var identifiers []string
+	if raw := r.URL.Query().Get("filter"); raw != "" {
+		identifiers = strings.Split(raw, ",")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		missed, err := publisher.Replay(ctx, lastID, identifiers)
+		if err != nil {
+			logger.Error("replay events failed", "last_event_id", lastID, "err", err)
+		}
+		for _, ev := range missed {
+			if err := writeEvent(w, ev, logger); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+
+	stream, cancel, err := publisher.Subscribe(ctx, identifiers)
+	if err != nil {
+		logger.Error("subscribe to events failed", "err", err)
+		return
+	}
+	defer cancel()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-stream:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, ev, logger); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev Event, logger *slog.Logger) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		logger.Error("serialize event failed", "event_type", string(ev.Type), "err", err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+	return err
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/patrickbucher/meow/middleware"
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	channel      = "endpoint.events"
+	streamKey    = "endpoint.events.stream"
+	streamMaxLen = 1000
+	readCount    = 1000
+)
+
+// valkeyPublisher is a Publisher backed by a Valkey PUBLISH/SUBSCRIBE
+// channel for live delivery and a capped stream for replay after a brief
+// disconnect.
+type valkeyPublisher struct {
+	client valkey.Client
+}
+
+// NewValkeyPublisher wraps an already-connected Valkey client as a
+// Publisher.
+func NewValkeyPublisher(client valkey.Client) Publisher {
+	return &valkeyPublisher{client: client}
+}
+
+func (p *valkeyPublisher) Publish(ctx context.Context, ev Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("serialize event: %w", err)
+	}
+
+	addCmd := p.client.B().Xadd().Key(streamKey).Maxlen().Almost().Threshold(strconv.Itoa(streamMaxLen)).
+		Id("*").FieldValue().FieldValue("data", string(data)).Build()
+	id, err := p.client.Do(ctx, addCmd).ToString()
+	if err != nil {
+		return fmt.Errorf("xadd %s: %w", streamKey, err)
+	}
+	ev.ID = id
+
+	published, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("serialize event: %w", err)
+	}
+	pubCmd := p.client.B().Publish().Channel(channel).Message(string(published)).Build()
+	if err := p.client.Do(ctx, pubCmd).Error(); err != nil {
+		return fmt.Errorf("publish %s: %w", channel, err)
+	}
+	return nil
+}
+
+func (p *valkeyPublisher) Subscribe(ctx context.Context, identifiers []string) (<-chan Event, func(), error) {
+	logger := middleware.LoggerFromContext(ctx)
+	out := make(chan Event, 16)
+	subCtx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		defer close(out)
+		err := p.client.Receive(subCtx, p.client.B().Subscribe().Channel(channel).Build(),
+			func(msg valkey.PubSubMessage) {
+				var ev Event
+				if err := json.Unmarshal([]byte(msg.Message), &ev); err != nil {
+					logger.Error("decode event message failed", "err", err)
+					return
+				}
+				if !matches(identifiers, ev.Identifier) {
+					return
+				}
+				select {
+				case out <- ev:
+				case <-subCtx.Done():
+				}
+			})
+		if err != nil && subCtx.Err() == nil {
+			logger.Error("subscribe to channel failed", "channel", channel, "err", err)
+		}
+	}()
+
+	return out, cancel, nil
+}
+
+func (p *valkeyPublisher) Replay(ctx context.Context, lastID string, identifiers []string) ([]Event, error) {
+	cmd := p.client.B().Xread().Count(readCount).Streams().Key(streamKey).Id(lastID).Build()
+	streams, err := p.client.Do(ctx, cmd).AsXRead()
+	if err != nil {
+		return nil, fmt.Errorf("xread %s: %w", streamKey, err)
+	}
+
+	var result []Event
+	for _, entry := range streams[streamKey] {
+		raw, ok := entry.FieldValues["data"]
+		if !ok {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+			continue
+		}
+		ev.ID = entry.ID
+		if matches(identifiers, ev.Identifier) {
+			result = append(result, ev)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+// Package httpcache memoizes serialized JSON responses for read-heavy GET
+// endpoints, using singleflight to collapse concurrent cache misses for
+// the same key into a single backend fetch.
+package httpcache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/patrickbucher/meow/middleware"
+	"golang.org/x/sync/singleflight"
+)
+
+// Result is the cacheable outcome of a Fetch: a status code and, for a
+// successful (200) response, its serialized body. Only 200 responses are
+// cached; everything else is served as-is and left out of the cache.
+type Result struct {
+	Status int
+	Data   []byte
+}
+
+// Fetch produces the Result for a cache miss.
+type Fetch func(ctx context.Context) (Result, error)
+
+type entry struct {
+	data     []byte
+	storedAt time.Time
+}
+
+// Cache memoizes GET responses for a fixed TTL, keyed by the request URL.
+type Cache struct {
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu      sync.RWMutex
+	entries map[string]entry
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+// NewCache returns a Cache that memoizes responses for ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: make(map[string]entry)}
+}
+
+// Handle serves key from the cache if fresh, otherwise calls fetch (with
+// concurrent misses for the same key coalesced via singleflight), writes
+// the result, and caches it if it was a 200. A "Cache-Control: no-cache"
+// request header skips the cache lookup; the fetched result still
+// replaces the cached entry. Cache-Control and Age
+// response headers are set to reflect the cache's state.
+func (c *Cache) Handle(w http.ResponseWriter, r *http.Request, key string, fetch Fetch) {
+	if !hasNoCacheDirective(r.Header.Get("Cache-Control")) {
+		if e, ok := c.lookup(key); ok {
+			c.hits.Add(1)
+			writeEntry(w, e, c.ttl)
+			return
+		}
+	}
+
+	fetched := false
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		fetched = true
+		res, err := fetch(r.Context())
+		if err != nil {
+			return Result{}, err
+		}
+		if res.Status == http.StatusOK {
+			c.store(key, entry{data: res.Data, storedAt: time.Now()})
+		}
+		return res, nil
+	})
+	if fetched {
+		c.misses.Add(1)
+	} else {
+		c.coalesced.Add(1)
+	}
+	if err != nil {
+		middleware.LoggerFromContext(r.Context()).Error("fetch failed", "key", key, "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res := v.(Result)
+	if res.Status != http.StatusOK {
+		w.WriteHeader(res.Status)
+		w.Write(res.Data)
+		return
+	}
+	writeEntry(w, entry{data: res.Data, storedAt: time.Now()}, c.ttl)
+}
+
+// Invalidate drops any cached entries for the given keys, e.g. after a
+// mutation that affects them.
+func (c *Cache) Invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+func (c *Cache) lookup(key string) (entry, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok || time.Since(e.storedAt) > c.ttl {
+		return entry{}, false
+	}
+	return e, true
+}
+
+func (c *Cache) store(key string, e entry) {
+	c.mu.Lock()
+	c.entries[key] = e
+	c.mu.Unlock()
+}
+
+func writeEntry(w http.ResponseWriter, e entry, ttl time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(ttl.Seconds())))
+	w.Header().Set("Age", fmt.Sprintf("%d", int(time.Since(e.storedAt).Seconds())))
+	w.Write(e.data)
+}
+
+func hasNoCacheDirective(cacheControl string) bool {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteMetrics writes the cache's hit/miss/coalesced counters in
+// Prometheus text exposition format.
+func (c *Cache) WriteMetrics(w http.ResponseWriter) {
+	fmt.Fprintln(w, "# HELP meow_http_cache_hits_total Cached GET responses served without a backend fetch.")
+	fmt.Fprintln(w, "# TYPE meow_http_cache_hits_total counter")
+	fmt.Fprintf(w, "meow_http_cache_hits_total %d\n", c.hits.Load())
+
+	fmt.Fprintln(w, "# HELP meow_http_cache_misses_total GET requests that triggered a backend fetch.")
+	fmt.Fprintln(w, "# TYPE meow_http_cache_misses_total counter")
+	fmt.Fprintf(w, "meow_http_cache_misses_total %d\n", c.misses.Load())
+
+	fmt.Fprintln(w, "# HELP meow_http_cache_coalesced_total Concurrent misses for the same key collapsed into one backend fetch.")
+	fmt.Fprintln(w, "# TYPE meow_http_cache_coalesced_total counter")
+	fmt.Fprintf(w, "meow_http_cache_coalesced_total %d\n", c.coalesced.Load())
+}
@@ -0,0 +1,86 @@
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandleCachesWithinTTL(t *testing.T) {
+	c := NewCache(time.Minute)
+	var calls atomic.Int32
+	fetch := func(ctx context.Context) (Result, error) {
+		calls.Add(1)
+		return Result{Status: http.StatusOK, Data: []byte(`{"ok":true}`)}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		c.Handle(rec, httptest.NewRequest(http.MethodGet, "/endpoints", nil), "/endpoints", fetch)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 backend fetch, got %d", calls.Load())
+	}
+}
+
+func TestHandleBypassesOnNoCache(t *testing.T) {
+	c := NewCache(time.Minute)
+	var calls atomic.Int32
+	fetch := func(ctx context.Context) (Result, error) {
+		calls.Add(1)
+		return Result{Status: http.StatusOK, Data: []byte(`{"ok":true}`)}, nil
+	}
+
+	c.Handle(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/endpoints", nil), "/endpoints", fetch)
+
+	req := httptest.NewRequest(http.MethodGet, "/endpoints", nil)
+	req.Header.Set("Cache-Control", "no-cache")
+	c.Handle(httptest.NewRecorder(), req, "/endpoints", fetch)
+
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 backend fetches, got %d", calls.Load())
+	}
+}
+
+func TestInvalidateForcesRefetch(t *testing.T) {
+	c := NewCache(time.Minute)
+	var calls atomic.Int32
+	fetch := func(ctx context.Context) (Result, error) {
+		calls.Add(1)
+		return Result{Status: http.StatusOK, Data: []byte(`{"ok":true}`)}, nil
+	}
+
+	c.Handle(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/endpoints", nil), "/endpoints", fetch)
+	c.Invalidate("/endpoints")
+	c.Handle(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/endpoints", nil), "/endpoints", fetch)
+
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 backend fetches after invalidation, got %d", calls.Load())
+	}
+}
+
+func TestHandleDoesNotCacheErrors(t *testing.T) {
+	c := NewCache(time.Minute)
+	var calls atomic.Int32
+	fetch := func(ctx context.Context) (Result, error) {
+		calls.Add(1)
+		return Result{Status: http.StatusNotFound}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		c.Handle(rec, httptest.NewRequest(http.MethodGet, "/endpoints/missing", nil), "/endpoints/missing", fetch)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 backend fetches for uncached errors, got %d", calls.Load())
+	}
+}
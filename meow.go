@@ -0,0 +1,76 @@
+// Package meow defines the domain types shared across the store,
+// monitor, and HTTP layers of the endpoint-monitoring service:
+// EndpointPayload is the serializable form persisted by stores and
+// returned over the API, while Endpoint and EndpointFromJSON parse and
+// hold the richer, validated form of a client-submitted definition.
+package meow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// EndpointPayload is the storage- and wire-level representation of a
+// monitored endpoint.
+type EndpointPayload struct {
+	Identifier   string `json:"identifier"`
+	URL          string `json:"url"`
+	Method       string `json:"method"`
+	StatusOnline uint16 `json:"status_online"`
+	Frequency    string `json:"frequency"`
+	FailAfter    uint8  `json:"fail_after"`
+}
+
+// Endpoint is the parsed form of an endpoint definition submitted by a
+// client, with URL and Frequency converted to their structured forms.
+type Endpoint struct {
+	Identifier   string
+	URL          *url.URL
+	Method       string
+	StatusOnline uint16
+	Frequency    time.Duration
+	FailAfter    uint8
+}
+
+// EndpointFromJSON parses raw as an endpoint definition.
+func EndpointFromJSON(raw string) (Endpoint, error) {
+	var body struct {
+		Identifier   string `json:"identifier"`
+		URL          string `json:"url"`
+		Method       string `json:"method"`
+		StatusOnline uint16 `json:"status_online"`
+		Frequency    string `json:"frequency"`
+		FailAfter    uint8  `json:"fail_after"`
+	}
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		return Endpoint{}, fmt.Errorf("unmarshal endpoint: %w", err)
+	}
+
+	if body.Identifier == "" {
+		return Endpoint{}, fmt.Errorf("identifier must not be empty")
+	}
+
+	u, err := url.Parse(body.URL)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("parse url %q: %w", body.URL, err)
+	}
+
+	frequency, err := time.ParseDuration(body.Frequency)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("parse frequency %q: %w", body.Frequency, err)
+	}
+	if frequency <= 0 {
+		return Endpoint{}, fmt.Errorf("frequency must be positive, got %q", body.Frequency)
+	}
+
+	return Endpoint{
+		Identifier:   body.Identifier,
+		URL:          u,
+		Method:       body.Method,
+		StatusOnline: body.StatusOnline,
+		Frequency:    frequency,
+		FailAfter:    body.FailAfter,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package meow
+
+import "testing"
+
+func TestEndpointFromJSON(t *testing.T) {
+	ep, err := EndpointFromJSON(`{
+		"identifier": "example",
+		"url": "https://example.com/health",
+		"method": "GET",
+		"status_online": 200,
+		"frequency": "10s",
+		"fail_after": 3
+	}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ep.Identifier != "example" {
+		t.Errorf("expected identifier %q, got %q", "example", ep.Identifier)
+	}
+	if ep.URL.String() != "https://example.com/health" {
+		t.Errorf("expected url %q, got %q", "https://example.com/health", ep.URL.String())
+	}
+	if ep.Frequency.String() != "10s" {
+		t.Errorf("expected frequency %q, got %q", "10s", ep.Frequency.String())
+	}
+}
+
+func TestEndpointFromJSONRejectsMissingIdentifier(t *testing.T) {
+	_, err := EndpointFromJSON(`{"url": "https://example.com/health", "frequency": "10s"}`)
+	if err == nil {
+		t.Fatal("expected error for missing identifier, got nil")
+	}
+}
+
+func TestEndpointFromJSONRejectsNonPositiveFrequency(t *testing.T) {
+	for _, frequency := range []string{"0s", "-1s"} {
+		_, err := EndpointFromJSON(`{
+			"identifier": "example",
+			"url": "https://example.com/health",
+			"frequency": "` + frequency + `"
+		}`)
+		if err == nil {
+			t.Errorf("expected error for frequency %q, got nil", frequency)
+		}
+	}
+}
+
+func TestEndpointFromJSONRejectsInvalidURL(t *testing.T) {
+	_, err := EndpointFromJSON(`{
+		"identifier": "example",
+		"url": "://bad",
+		"frequency": "10s"
+	}`)
+	if err == nil {
+		t.Fatal("expected error for invalid url, got nil")
+	}
+}
@@ -0,0 +1,9 @@
+// Package middleware provides a small chain of http.Handler wrappers
+// (request IDs, access logging, panic recovery, auth, CORS, gzip) applied
+// uniformly across the API's routes.
+package middleware
+
+import "net/http"
+
+// Middleware wraps an http.Handler with additional behavior.
+type Middleware func(http.Handler) http.Handler
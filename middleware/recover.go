@@ -0,0 +1,18 @@
+package middleware
+
+import "net/http"
+
+// Recover catches panics from downstream handlers, logs them, and
+// responds with 500 Internal Server Error instead of taking the server
+// down.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				LoggerFromContext(r.Context()).Error("panic recovered", "panic", rec)
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/patrickbucher/meow/middleware"
+)
+
+// GetHistory handles GET /endpoints/{id}/history?since=&limit=, returning
+// the recorded samples for the given identifier as JSON.
+func GetHistory(w http.ResponseWriter, r *http.Request, identifier string, scheduler *Scheduler) {
+	logger := middleware.LoggerFromContext(r.Context())
+	query := r.URL.Query()
+
+	var since time.Time
+	if raw := query.Get("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			logger.Warn("parse since query param failed", "identifier", identifier, "since", raw, "err", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			logger.Warn("parse limit query param failed", "identifier", identifier, "limit", raw, "err", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	samples, err := scheduler.History(r.Context(), identifier, since, limit)
+	if err != nil {
+		logger.Error("load history failed", "identifier", identifier, "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(samples)
+	if err != nil {
+		logger.Error("serialize history failed", "identifier", identifier, "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// GetMetrics handles GET /metrics, exposing per-endpoint gauges and
+// counters in Prometheus text exposition format.
+func GetMetrics(w http.ResponseWriter, r *http.Request, scheduler *Scheduler) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	scheduler.WriteMetrics(w)
+}
@@ -0,0 +1,248 @@
+// Package monitor actively probes the endpoints held in an EndpointStore,
+// records their status history, and exposes aggregate metrics.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/patrickbucher/meow"
+	"github.com/patrickbucher/meow/events"
+	"github.com/patrickbucher/meow/store"
+)
+
+type endpointState struct {
+	up                  atomic.Bool
+	consecutiveFailures atomic.Uint32
+	lastLatencyMs       atomic.Int64
+	checksTotal         atomic.Uint64
+	failuresTotal       atomic.Uint64
+}
+
+type watch struct {
+	payload meow.EndpointPayload
+	state   *endpointState
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Scheduler runs one probing goroutine per monitored endpoint and keeps
+// their state in memory while persisting probe history through an
+// EndpointStore.
+type Scheduler struct {
+	store     store.EndpointStore
+	publisher events.Publisher
+
+	mu      sync.Mutex
+	watches map[string]*watch
+}
+
+// NewScheduler creates a Scheduler backed by the given EndpointStore,
+// publishing status transitions (up/down) through publisher.
+func NewScheduler(s store.EndpointStore, publisher events.Publisher) *Scheduler {
+	return &Scheduler{
+		store:     s,
+		publisher: publisher,
+		watches:   make(map[string]*watch),
+	}
+}
+
+// Start loads every endpoint currently in the store and begins probing it.
+// It returns once the initial set of endpoints has been scheduled;
+// probing continues in background goroutines until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) error {
+	payloads, err := s.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list endpoints: %w", err)
+	}
+	for _, payload := range payloads {
+		s.Reschedule(ctx, payload)
+	}
+	return nil
+}
+
+// Reschedule (re-)starts probing for the given endpoint, replacing any
+// ticker already running for the same identifier. It is meant to be called
+// whenever an endpoint definition is created or updated.
+func (s *Scheduler) Reschedule(parent context.Context, payload meow.EndpointPayload) {
+	s.Remove(payload.Identifier)
+
+	frequency, err := time.ParseDuration(payload.Frequency)
+	if err != nil || frequency <= 0 {
+		log.Printf("monitor: endpoint %q has invalid frequency %q, not scheduling",
+			payload.Identifier, payload.Frequency)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	w := &watch{
+		payload: payload,
+		state:   &endpointState{},
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	w.state.up.Store(true)
+
+	s.mu.Lock()
+	s.watches[payload.Identifier] = w
+	s.mu.Unlock()
+
+	go s.run(ctx, w, frequency)
+}
+
+// Remove stops probing the endpoint with the given identifier, if any.
+func (s *Scheduler) Remove(identifier string) {
+	s.mu.Lock()
+	w, ok := s.watches[identifier]
+	if ok {
+		delete(s.watches, identifier)
+	}
+	s.mu.Unlock()
+	if ok {
+		w.cancel()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, w *watch, frequency time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(frequency)
+	defer ticker.Stop()
+
+	s.probeOnce(ctx, w, frequency)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.probeOnce(ctx, w, frequency)
+		}
+	}
+}
+
+func (s *Scheduler) probeOnce(ctx context.Context, w *watch, frequency time.Duration) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("monitor: probe of %q panicked: %v", w.payload.Identifier, r)
+		}
+	}()
+
+	timeout := frequency / 2
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	req, err := http.NewRequestWithContext(probeCtx, w.payload.Method, w.payload.URL, nil)
+	var statusCode int
+	success := false
+	if err == nil {
+		resp, doErr := http.DefaultClient.Do(req)
+		if doErr == nil {
+			statusCode = resp.StatusCode
+			resp.Body.Close()
+			success = statusCode == int(w.payload.StatusOnline)
+		}
+	}
+	latency := time.Since(start)
+
+	wasUp := w.state.up.Load()
+
+	w.state.checksTotal.Add(1)
+	w.state.lastLatencyMs.Store(latency.Milliseconds())
+	if success {
+		w.state.up.Store(true)
+		w.state.consecutiveFailures.Store(0)
+	} else {
+		w.state.failuresTotal.Add(1)
+		failures := w.state.consecutiveFailures.Add(1)
+		if failures >= uint32(w.payload.FailAfter) {
+			w.state.up.Store(false)
+		}
+	}
+
+	if nowUp := w.state.up.Load(); nowUp != wasUp && s.publisher != nil {
+		up := nowUp
+		ev := events.Event{
+			Type:       events.StatusChanged,
+			Identifier: w.payload.Identifier,
+			Timestamp:  time.Now(),
+			Up:         &up,
+		}
+		if err := s.publisher.Publish(ctx, ev); err != nil {
+			log.Printf("monitor: publish status change for %q: %v", w.payload.Identifier, err)
+		}
+	}
+
+	sample := store.Sample{
+		Timestamp:  start,
+		StatusCode: statusCode,
+		LatencyMs:  latency.Milliseconds(),
+		Success:    success,
+	}
+	if err := s.store.AppendHistory(ctx, w.payload.Identifier, sample); err != nil {
+		log.Printf("monitor: record history for %q: %v", w.payload.Identifier, err)
+	}
+}
+
+// History returns the recorded samples for identifier, optionally filtered
+// to samples observed at or after since and capped at limit entries (0
+// means no cap).
+func (s *Scheduler) History(ctx context.Context, identifier string, since time.Time, limit int) ([]store.Sample, error) {
+	return s.store.LoadHistory(ctx, identifier, since, limit)
+}
+
+// WriteMetrics writes the current per-endpoint gauges and counters in
+// Prometheus text exposition format.
+func (s *Scheduler) WriteMetrics(w http.ResponseWriter) {
+	s.mu.Lock()
+	watches := make([]*watch, 0, len(s.watches))
+	for _, watch := range s.watches {
+		watches = append(watches, watch)
+	}
+	s.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP meow_endpoint_up Whether the endpoint is currently considered up (1) or down (0).")
+	fmt.Fprintln(w, "# TYPE meow_endpoint_up gauge")
+	for _, wt := range watches {
+		up := 0
+		if wt.state.up.Load() {
+			up = 1
+		}
+		fmt.Fprintf(w, "meow_endpoint_up{identifier=%q} %d\n", wt.payload.Identifier, up)
+	}
+
+	fmt.Fprintln(w, "# HELP meow_endpoint_last_latency_ms Latency of the last probe in milliseconds.")
+	fmt.Fprintln(w, "# TYPE meow_endpoint_last_latency_ms gauge")
+	for _, wt := range watches {
+		fmt.Fprintf(w, "meow_endpoint_last_latency_ms{identifier=%q} %d\n",
+			wt.payload.Identifier, wt.state.lastLatencyMs.Load())
+	}
+
+	fmt.Fprintln(w, "# HELP meow_endpoint_consecutive_failures Number of consecutive failed probes.")
+	fmt.Fprintln(w, "# TYPE meow_endpoint_consecutive_failures gauge")
+	for _, wt := range watches {
+		fmt.Fprintf(w, "meow_endpoint_consecutive_failures{identifier=%q} %d\n",
+			wt.payload.Identifier, wt.state.consecutiveFailures.Load())
+	}
+
+	fmt.Fprintln(w, "# HELP meow_endpoint_checks_total Total number of probes issued.")
+	fmt.Fprintln(w, "# TYPE meow_endpoint_checks_total counter")
+	for _, wt := range watches {
+		fmt.Fprintf(w, "meow_endpoint_checks_total{identifier=%q} %d\n",
+			wt.payload.Identifier, wt.state.checksTotal.Load())
+	}
+
+	fmt.Fprintln(w, "# HELP meow_endpoint_failures_total Total number of failed probes.")
+	fmt.Fprintln(w, "# TYPE meow_endpoint_failures_total counter")
+	for _, wt := range watches {
+		fmt.Fprintf(w, "meow_endpoint_failures_total{identifier=%q} %d\n",
+			wt.payload.Identifier, wt.state.failuresTotal.Load())
+	}
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/patrickbucher/meow"
+)
+
+// memoryStore is an in-memory EndpointStore, mainly useful for tests and
+// local development without a running Valkey instance.
+type memoryStore struct {
+	mu        sync.Mutex
+	endpoints map[string]meow.EndpointPayload
+	history   map[string][]Sample
+}
+
+// NewMemoryStore returns an EndpointStore that keeps everything in memory
+// and is lost on process restart.
+func NewMemoryStore() EndpointStore {
+	return &memoryStore{
+		endpoints: make(map[string]meow.EndpointPayload),
+		history:   make(map[string][]Sample),
+	}
+}
+
+func (s *memoryStore) Get(ctx context.Context, id string) (meow.EndpointPayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ep, ok := s.endpoints[id]
+	if !ok {
+		return meow.EndpointPayload{}, ErrNotFound
+	}
+	return ep, nil
+}
+
+func (s *memoryStore) Put(ctx context.Context, ep meow.EndpointPayload) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.endpoints[ep.Identifier]
+	s.endpoints[ep.Identifier] = ep
+	return !exists, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.endpoints, id)
+	delete(s.history, id)
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context) ([]meow.EndpointPayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payloads := make([]meow.EndpointPayload, 0, len(s.endpoints))
+	for _, ep := range s.endpoints {
+		payloads = append(payloads, ep)
+	}
+	sort.Slice(payloads, func(i, j int) bool {
+		return payloads[i].Identifier < payloads[j].Identifier
+	})
+	return payloads, nil
+}
+
+func (s *memoryStore) AppendHistory(ctx context.Context, id string, sample Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[id] = append(s.history[id], sample)
+	if len(s.history[id]) > historyLimit {
+		s.history[id] = s.history[id][len(s.history[id])-historyLimit:]
+	}
+	return nil
+}
+
+func (s *memoryStore) LoadHistory(ctx context.Context, id string, since time.Time, limit int) ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	samples := make([]Sample, 0, len(s.history[id]))
+	for _, sample := range s.history[id] {
+		if !since.IsZero() && sample.Timestamp.Before(since) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if limit > 0 && len(samples) > limit {
+		samples = samples[len(samples)-limit:]
+	}
+	return samples, nil
+}
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// New builds an EndpointStore of the given kind ("valkey", "memory", or
+// "postgres"). dsn is ignored for "memory", used as the Postgres
+// connection string for "postgres", and ignored for "valkey" (which is
+// configured via client instead).
+func New(kind, dsn string, client valkey.Client) (EndpointStore, error) {
+	switch kind {
+	case "", "valkey":
+		return NewValkeyStore(client), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		if dsn == "" {
+			return nil, fmt.Errorf("store: postgres backend requires a DSN (-store-dsn or STORE_DSN)")
+		}
+		return NewPostgresStore(dsn)
+	default:
+		return nil, fmt.Errorf("store: unknown backend %q", kind)
+	}
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/patrickbucher/meow"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresStore is an EndpointStore backed by a Postgres database. It
+// expects the following schema to already exist:
+//
+//	CREATE TABLE endpoints (
+//	    identifier    TEXT PRIMARY KEY,
+//	    url           TEXT NOT NULL,
+//	    method        TEXT NOT NULL,
+//	    status_online INTEGER NOT NULL,
+//	    frequency     TEXT NOT NULL,
+//	    fail_after    INTEGER NOT NULL
+//	);
+//	CREATE TABLE endpoint_history (
+//	    identifier TEXT NOT NULL REFERENCES endpoints(identifier) ON DELETE CASCADE,
+//	    sample     JSONB NOT NULL,
+//	    recorded_at TIMESTAMPTZ NOT NULL
+//	);
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore opens a connection pool to the Postgres database
+// identified by dsn.
+func NewPostgresStore(dsn string) (EndpointStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("ping postgres: %w", err)
+	}
+	return &postgresStore{db: db}, nil
+}
+
+func (s *postgresStore) Get(ctx context.Context, id string) (meow.EndpointPayload, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT identifier, url, method, status_online, frequency, fail_after
+		 FROM endpoints WHERE identifier = $1`, id)
+
+	var ep meow.EndpointPayload
+	if err := row.Scan(&ep.Identifier, &ep.URL, &ep.Method, &ep.StatusOnline,
+		&ep.Frequency, &ep.FailAfter); err != nil {
+		if err == sql.ErrNoRows {
+			return meow.EndpointPayload{}, ErrNotFound
+		}
+		return meow.EndpointPayload{}, fmt.Errorf("query endpoint %q: %w", id, err)
+	}
+	return ep, nil
+}
+
+func (s *postgresStore) Put(ctx context.Context, ep meow.EndpointPayload) (bool, error) {
+	var inserted bool
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO endpoints (identifier, url, method, status_online, frequency, fail_after)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (identifier) DO UPDATE SET
+			url = EXCLUDED.url,
+			method = EXCLUDED.method,
+			status_online = EXCLUDED.status_online,
+			frequency = EXCLUDED.frequency,
+			fail_after = EXCLUDED.fail_after
+		RETURNING (xmax = 0) AS inserted`,
+		ep.Identifier, ep.URL, ep.Method, ep.StatusOnline, ep.Frequency, ep.FailAfter).Scan(&inserted)
+	if err != nil {
+		return false, fmt.Errorf("upsert endpoint %q: %w", ep.Identifier, err)
+	}
+	return inserted, nil
+}
+
+func (s *postgresStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM endpoints WHERE identifier = $1`, id); err != nil {
+		return fmt.Errorf("delete endpoint %q: %w", id, err)
+	}
+	return nil
+}
+
+func (s *postgresStore) List(ctx context.Context) ([]meow.EndpointPayload, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT identifier, url, method, status_online, frequency, fail_after
+		 FROM endpoints ORDER BY identifier`)
+	if err != nil {
+		return nil, fmt.Errorf("list endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	payloads := make([]meow.EndpointPayload, 0)
+	for rows.Next() {
+		var ep meow.EndpointPayload
+		if err := rows.Scan(&ep.Identifier, &ep.URL, &ep.Method, &ep.StatusOnline,
+			&ep.Frequency, &ep.FailAfter); err != nil {
+			return nil, fmt.Errorf("scan endpoint: %w", err)
+		}
+		payloads = append(payloads, ep)
+	}
+	return payloads, rows.Err()
+}
+
+func (s *postgresStore) AppendHistory(ctx context.Context, id string, sample Sample) error {
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("serialize sample for %q: %w", id, err)
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO endpoint_history (identifier, sample, recorded_at) VALUES ($1, $2, $3)`,
+		id, data, sample.Timestamp); err != nil {
+		return fmt.Errorf("insert history for %q: %w", id, err)
+	}
+	return nil
+}
+
+func (s *postgresStore) LoadHistory(ctx context.Context, id string, since time.Time, limit int) ([]Sample, error) {
+	query := `SELECT sample FROM endpoint_history WHERE identifier = $1`
+	args := []any{id}
+	if !since.IsZero() {
+		query += fmt.Sprintf(" AND recorded_at >= $%d", len(args)+1)
+		args = append(args, since)
+	}
+	query += " ORDER BY recorded_at ASC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query history for %q: %w", id, err)
+	}
+	defer rows.Close()
+
+	samples := make([]Sample, 0)
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan history sample: %w", err)
+		}
+		var sample Sample
+		if err := json.Unmarshal(raw, &sample); err != nil {
+			return nil, fmt.Errorf("unmarshal history sample: %w", err)
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
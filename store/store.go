@@ -0,0 +1,51 @@
+// Package store decouples the HTTP handlers from the concrete database
+// used to persist endpoint definitions and their probe history.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/patrickbucher/meow"
+)
+
+// ErrNotFound is returned by Get when no endpoint with the given
+// identifier exists.
+var ErrNotFound = errors.New("endpoint not found")
+
+// Sample is a single observation of an endpoint's health, as recorded by
+// the monitor subsystem.
+type Sample struct {
+	Timestamp  time.Time `json:"timestamp"`
+	StatusCode int       `json:"status_code"`
+	LatencyMs  int64     `json:"latency_ms"`
+	Success    bool      `json:"success"`
+}
+
+// EndpointStore persists endpoint definitions and their probe history.
+// Implementations must be safe for concurrent use.
+type EndpointStore interface {
+	// Get returns the endpoint with the given identifier, or ErrNotFound
+	// if it does not exist.
+	Get(ctx context.Context, id string) (meow.EndpointPayload, error)
+
+	// Put creates or updates the given endpoint. created is true if no
+	// endpoint with that identifier existed before.
+	Put(ctx context.Context, ep meow.EndpointPayload) (created bool, err error)
+
+	// Delete removes the endpoint with the given identifier. It is a
+	// no-op, not an error, if the endpoint does not exist.
+	Delete(ctx context.Context, id string) error
+
+	// List returns every stored endpoint.
+	List(ctx context.Context) ([]meow.EndpointPayload, error)
+
+	// AppendHistory records a probe sample for the given identifier.
+	AppendHistory(ctx context.Context, id string, sample Sample) error
+
+	// LoadHistory returns the samples recorded for the given identifier,
+	// oldest first, optionally filtered to samples at or after since and
+	// capped at limit entries (0 means no cap).
+	LoadHistory(ctx context.Context, id string, since time.Time, limit int) ([]Sample, error)
+}
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/patrickbucher/meow"
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	endpointKeyPrefix = "endpoint:"
+	historyKeyPrefix  = "history:"
+	historyLimit      = 1000
+	scanCount         = 200
+)
+
+// valkeyStore is an EndpointStore backed by a Valkey instance.
+type valkeyStore struct {
+	client valkey.Client
+}
+
+// NewValkeyStore wraps an already-connected Valkey client as an
+// EndpointStore.
+func NewValkeyStore(client valkey.Client) EndpointStore {
+	return &valkeyStore{client: client}
+}
+
+func (s *valkeyStore) Get(ctx context.Context, id string) (meow.EndpointPayload, error) {
+	key := endpointKeyPrefix + id
+	kvs, err := s.client.Do(ctx, s.client.B().Hgetall().Key(key).Build()).AsStrMap()
+	if err != nil {
+		return meow.EndpointPayload{}, fmt.Errorf("hgetall %s: %w", key, err)
+	}
+	if len(kvs) == 0 {
+		return meow.EndpointPayload{}, ErrNotFound
+	}
+	return payloadFromMap(kvs), nil
+}
+
+func (s *valkeyStore) Put(ctx context.Context, ep meow.EndpointPayload) (bool, error) {
+	key := endpointKeyPrefix + ep.Identifier
+
+	exists, err := s.client.Do(ctx, s.client.B().Exists().Key(key).Build()).AsInt64()
+	if err != nil {
+		return false, fmt.Errorf("check existence of %s: %w", key, err)
+	}
+
+	cmd := s.client.B().Hset().Key(key).
+		FieldValue().
+		FieldValue("identifier", ep.Identifier).
+		FieldValue("url", ep.URL).
+		FieldValue("method", ep.Method).
+		FieldValue("status_online", strconv.Itoa(int(ep.StatusOnline))).
+		FieldValue("frequency", ep.Frequency).
+		FieldValue("fail_after", strconv.Itoa(int(ep.FailAfter))).
+		Build()
+	if err := s.client.Do(ctx, cmd).Error(); err != nil {
+		return false, fmt.Errorf("hset %s: %w", key, err)
+	}
+
+	return exists == 0, nil
+}
+
+func (s *valkeyStore) Delete(ctx context.Context, id string) error {
+	key := endpointKeyPrefix + id
+	if err := s.client.Do(ctx, s.client.B().Del().Key(key).Build()).Error(); err != nil {
+		return fmt.Errorf("del %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *valkeyStore) List(ctx context.Context) ([]meow.EndpointPayload, error) {
+	keys, err := s.scanKeys(ctx, endpointKeyPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := make([]meow.EndpointPayload, 0, len(keys))
+	for _, key := range keys {
+		kvs, err := s.client.Do(ctx, s.client.B().Hgetall().Key(key).Build()).AsStrMap()
+		if err != nil || len(kvs) == 0 {
+			continue
+		}
+		payloads = append(payloads, payloadFromMap(kvs))
+	}
+	return payloads, nil
+}
+
+// scanKeys walks the keyspace with SCAN rather than KEYS, which blocks the
+// whole server while it iterates a potentially large keyspace.
+func (s *valkeyStore) scanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	cursor := uint64(0)
+	for {
+		entry, err := s.client.Do(ctx, s.client.B().Scan().Cursor(cursor).
+			Match(pattern).Count(scanCount).Build()).AsScanEntry()
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", pattern, err)
+		}
+		keys = append(keys, entry.Elements...)
+		if entry.Cursor == 0 {
+			break
+		}
+		cursor = entry.Cursor
+	}
+	return keys, nil
+}
+
+func (s *valkeyStore) AppendHistory(ctx context.Context, id string, sample Sample) error {
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("serialize sample for %q: %w", id, err)
+	}
+	key := historyKeyPrefix + id
+	cmds := []valkey.Completed{
+		s.client.B().Rpush().Key(key).Element(string(data)).Build(),
+		s.client.B().Ltrim().Key(key).Start(-historyLimit).Stop(-1).Build(),
+	}
+	for _, resp := range s.client.DoMulti(ctx, cmds...) {
+		if err := resp.Error(); err != nil {
+			return fmt.Errorf("record history for %q: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *valkeyStore) LoadHistory(ctx context.Context, id string, since time.Time, limit int) ([]Sample, error) {
+	key := historyKeyPrefix + id
+	raw, err := s.client.Do(ctx, s.client.B().Lrange().Key(key).Start(0).Stop(-1).Build()).AsStrSlice()
+	if err != nil {
+		return nil, fmt.Errorf("lrange %s: %w", key, err)
+	}
+
+	samples := make([]Sample, 0, len(raw))
+	for _, item := range raw {
+		var sample Sample
+		if err := json.Unmarshal([]byte(item), &sample); err != nil {
+			continue
+		}
+		if !since.IsZero() && sample.Timestamp.Before(since) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if limit > 0 && len(samples) > limit {
+		samples = samples[len(samples)-limit:]
+	}
+	return samples, nil
+}
+
+func payloadFromMap(kvs map[string]string) meow.EndpointPayload {
+	return meow.EndpointPayload{
+		Identifier:   kvs["identifier"],
+		URL:          kvs["url"],
+		Method:       kvs["method"],
+		StatusOnline: parseUint16(kvs["status_online"]),
+		Frequency:    kvs["frequency"],
+		FailAfter:    parseUint8(kvs["fail_after"]),
+	}
+}
+
+func parseUint16(s string) uint16 {
+	val, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(val)
+}
+
+func parseUint8(s string) uint8 {
+	val, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0
+	}
+	return uint8(val)
+}